@@ -1,16 +1,167 @@
 package framework
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/vault/helper/parseutil"
 	"github.com/hashicorp/vault/helper/strutil"
 	"github.com/mitchellh/mapstructure"
 )
 
+// FieldType is an enum of the type of a field in a FieldSchema.
+type FieldType uint
+
+const (
+	TypeInvalid FieldType = 0
+	TypeString  FieldType = iota
+	TypeNameString
+	TypeInt
+	TypeBool
+	TypeMap
+	TypeDurationSecond
+	TypeSlice
+	TypeStringSlice
+	TypeCommaStringSlice
+	TypeFloat
+	TypeStringMap
+	TypeSet
+	TypeJSONString
+	TypeBase64
+)
+
+func (t FieldType) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeNameString:
+		return "name string"
+	case TypeInt:
+		return "int"
+	case TypeBool:
+		return "bool"
+	case TypeMap:
+		return "map"
+	case TypeDurationSecond:
+		return "duration (sec)"
+	case TypeSlice:
+		return "slice"
+	case TypeStringSlice:
+		return "string slice"
+	case TypeCommaStringSlice:
+		return "comma-separated string slice"
+	case TypeFloat:
+		return "float"
+	case TypeStringMap:
+		return "string map"
+	case TypeSet:
+		return "set"
+	case TypeJSONString:
+		return "JSON string"
+	case TypeBase64:
+		return "base64 string"
+	default:
+		return "unknown type"
+	}
+}
+
+// ValidateFunc is used to validate a single field value after it has been
+// coerced to its declared type. It returns any warnings the caller should
+// surface, plus any errors that should fail validation. It must not mutate
+// the value it is given.
+type ValidateFunc func(interface{}) ([]string, []error)
+
+// FieldSchema is a single field in a schema for a path, used to describe
+// the parameters accepted by a backend callback and how to validate and
+// default them.
+type FieldSchema struct {
+	Type        FieldType
+	Default     interface{}
+	Description string
+	Required    bool
+	Deprecated  bool
+
+	// DefaultFunc, if set, is preferred over the static Default value
+	// whenever the field is unset, following the pattern used by
+	// Terraform's schemaMap.validate. It allows for environment-derived
+	// defaults (os.Getenv), time-based defaults, and lazy loading of
+	// expensive defaults without forcing callers to precompute them at
+	// schema registration.
+	DefaultFunc func() (interface{}, error)
+
+	// ValidateFunc, if set, is run against the coerced field value in
+	// addition to the primitive type coercion FieldData already performs.
+	ValidateFunc ValidateFunc
+
+	// ConflictsWith, RequiredWith, ExactlyOneOf, and AtLeastOneOf describe
+	// cross-field constraints that FieldData.Validate checks against the
+	// raw input, independent of any single field's own ValidateFunc. They
+	// are modeled on the equivalent Terraform helper/schema constructs.
+	ConflictsWith []string
+	RequiredWith  []string
+	ExactlyOneOf  []string
+	AtLeastOneOf  []string
+}
+
+// DefaultValue returns the field's default value: the result of
+// DefaultFunc if set, otherwise the static Default, otherwise the zero
+// value for the field's type. Unlike DefaultOrZero, it surfaces any
+// error DefaultFunc returns instead of panicking.
+func (s *FieldSchema) DefaultValue() (interface{}, error) {
+	if s.DefaultFunc != nil {
+		return s.DefaultFunc()
+	}
+
+	return s.DefaultOrZero(), nil
+}
+
+// DefaultOrZero returns the default value for the field, or the zero
+// value if no default is set. If DefaultFunc is set and returns an
+// error, this panics; use DefaultValue to handle that error instead.
+func (s *FieldSchema) DefaultOrZero() interface{} {
+	if s.DefaultFunc != nil {
+		value, err := s.DefaultFunc()
+		if err != nil {
+			panic(fmt.Sprintf("error getting default value: %s", err))
+		}
+		return value
+	}
+
+	if s.Default != nil {
+		return s.Default
+	}
+
+	switch s.Type {
+	case TypeString, TypeNameString:
+		return ""
+	case TypeInt:
+		return 0
+	case TypeBool:
+		return false
+	case TypeMap:
+		return map[string]interface{}{}
+	case TypeDurationSecond:
+		return 0
+	case TypeSlice:
+		return []interface{}{}
+	case TypeStringSlice, TypeCommaStringSlice, TypeSet:
+		return []string{}
+	case TypeFloat:
+		return float64(0)
+	case TypeStringMap:
+		return map[string]string{}
+	case TypeJSONString, TypeBase64:
+		return ""
+	default:
+		panic(fmt.Sprintf("unknown type: %s", s.Type))
+	}
+}
+
 // FieldData is the structure passed to the callback to handle a path
 // containing the populated parameters for fields. This should be used
 // instead of the raw (*vault.Request).Data to access data in a type-safe
@@ -18,15 +169,57 @@ import (
 type FieldData struct {
 	Raw    map[string]interface{}
 	Schema map[string]*FieldSchema
+
+	// Strict, when true, makes Validate reject any key in Raw that has
+	// no corresponding entry in Schema, instead of silently ignoring it.
+	// Callbacks that want this behavior should set it on the FieldData
+	// they build for a request; it defaults to false to preserve the
+	// historical lenient behavior.
+	Strict bool
+
+	// DecodeHooks, if set, are passed to the mapstructure decoder Decode
+	// builds, letting callers register custom conversions (e.g.
+	// string->time.Duration, string->net.IP) on top of the coercion
+	// getPrimitive already performs.
+	DecodeHooks []mapstructure.DecodeHookFunc
 }
 
-// Validate cycles through raw data and validate conversions in
-// the schema, so we don't get an error/panic later when
-// trying to get data out.  Data not in the schema is not
-// an error at this point, so we don't worry about it.
-func (d *FieldData) Validate() error {
-	for field, value := range d.Raw {
+// multiError aggregates several errors into one, following the same
+// "N errors occurred" rendering as github.com/hashicorp/go-multierror,
+// without pulling in the dependency.
+type multiError struct {
+	Errors []error
+}
+
+func (e *multiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
 
+	points := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		points[i] = fmt.Sprintf("* %s", err)
+	}
+
+	return fmt.Sprintf(
+		"%d errors occurred:\n\t%s\n",
+		len(e.Errors), strings.Join(points, "\n\t"))
+}
+
+// Validate cycles through raw data and validates conversions in the
+// schema, so we don't get an error/panic later when trying to get data
+// out. It also runs each field's ValidateFunc (if any) and the
+// declarative cross-field constraints (ConflictsWith, RequiredWith,
+// ExactlyOneOf, AtLeastOneOf) against d.Raw. All problems found are
+// aggregated rather than returned on the first failure; warnings are
+// returned separately from errors since they don't fail validation.
+// When d.Strict is set, any key in d.Raw with no matching entry in
+// d.Schema is also reported as an error instead of being ignored.
+func (d *FieldData) Validate() ([]string, error) {
+	var warnings []string
+	var errs []error
+
+	for field, value := range d.Raw {
 		schema, ok := d.Schema[field]
 		if !ok {
 			continue
@@ -34,18 +227,111 @@ func (d *FieldData) Validate() error {
 
 		switch schema.Type {
 		case TypeBool, TypeInt, TypeMap, TypeDurationSecond, TypeString,
-			TypeNameString, TypeSlice, TypeStringSlice, TypeCommaStringSlice:
-			_, _, err := d.getPrimitive(field, schema)
+			TypeNameString, TypeSlice, TypeStringSlice, TypeCommaStringSlice,
+			TypeFloat, TypeStringMap, TypeSet, TypeJSONString, TypeBase64:
+			result, _, err := d.getPrimitive(field, schema)
 			if err != nil {
-				return fmt.Errorf("Error converting input %v for field %s: %s", value, field, err)
+				errs = append(errs, fmt.Errorf("Error converting input %v for field %s: %s", value, field, err))
+				continue
+			}
+
+			if schema.ValidateFunc != nil {
+				w, e := schema.ValidateFunc(result)
+				warnings = append(warnings, w...)
+				for _, verr := range e {
+					errs = append(errs, fmt.Errorf("%s: %s", field, verr))
+				}
 			}
 		default:
-			return fmt.Errorf("unknown field type %s for field %s",
-				schema.Type, field)
+			errs = append(errs, fmt.Errorf("unknown field type %s for field %s", schema.Type, field))
 		}
 	}
 
-	return nil
+	seenGroups := map[string]bool{}
+	for field, schema := range d.Schema {
+		errs = append(errs, d.validateConstraints(field, schema, seenGroups)...)
+	}
+
+	if d.Strict {
+		for field := range d.Raw {
+			if _, ok := d.Schema[field]; !ok {
+				errs = append(errs, fmt.Errorf("invalid or unknown key: %q", field))
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+
+	return warnings, &multiError{Errors: errs}
+}
+
+// validateConstraints checks the declarative cross-field constraints on
+// schema against d.Raw. ExactlyOneOf and AtLeastOneOf are evaluated once
+// per distinct group rather than once per field, so a group shared by
+// several fields doesn't produce one error per member; seenGroups tracks
+// which groups have already been checked by their canonical (sorted)
+// form, since different fields may list the same group in a different
+// order.
+func (d *FieldData) validateConstraints(field string, schema *FieldSchema, seenGroups map[string]bool) []error {
+	var errs []error
+
+	_, isSet := d.Raw[field]
+
+	if isSet {
+		for _, other := range schema.ConflictsWith {
+			if _, ok := d.Raw[other]; ok {
+				errs = append(errs, fmt.Errorf("%q conflicts with %q", field, other))
+			}
+		}
+
+		for _, other := range schema.RequiredWith {
+			if _, ok := d.Raw[other]; !ok {
+				errs = append(errs, fmt.Errorf("%q requires %q to also be set", field, other))
+			}
+		}
+	}
+
+	if len(schema.ExactlyOneOf) > 0 && !seenGroups[groupKey("exactly-one", schema.ExactlyOneOf)] {
+		seenGroups[groupKey("exactly-one", schema.ExactlyOneOf)] = true
+
+		count := 0
+		for _, name := range schema.ExactlyOneOf {
+			if _, ok := d.Raw[name]; ok {
+				count++
+			}
+		}
+		if count != 1 {
+			errs = append(errs, fmt.Errorf("exactly one of %s must be set", strings.Join(schema.ExactlyOneOf, ", ")))
+		}
+	}
+
+	if len(schema.AtLeastOneOf) > 0 && !seenGroups[groupKey("at-least-one", schema.AtLeastOneOf)] {
+		seenGroups[groupKey("at-least-one", schema.AtLeastOneOf)] = true
+
+		any := false
+		for _, name := range schema.AtLeastOneOf {
+			if _, ok := d.Raw[name]; ok {
+				any = true
+				break
+			}
+		}
+		if !any {
+			errs = append(errs, fmt.Errorf("at least one of %s must be set", strings.Join(schema.AtLeastOneOf, ", ")))
+		}
+	}
+
+	return errs
+}
+
+// groupKey builds a canonical key for a constraint group so that the
+// same set of field names is recognized as one group regardless of the
+// order a particular field lists them in.
+func groupKey(kind string, names []string) string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	return kind + ":" + strings.Join(sorted, "\x00")
 }
 
 // Get gets the value for the given field. If the key is an invalid field,
@@ -59,7 +345,7 @@ func (d *FieldData) Get(k string) interface{} {
 	}
 
 	value, ok := d.GetOk(k)
-	if !ok {
+	if !ok && schema.DefaultFunc == nil {
 		value = schema.DefaultOrZero()
 	}
 
@@ -78,6 +364,43 @@ func (d *FieldData) GetDefaultOrZero(k string) interface{} {
 	return schema.DefaultOrZero()
 }
 
+// Decode populates out, a pointer to a struct, with every field declared
+// in d.Schema, coerced through the same getPrimitive machinery Get and
+// GetOk use. Struct fields are matched by their `mapstructure:"..."` tag,
+// so out can embed other structs (tag the embedded field
+// `mapstructure:",squash"`) to share config shapes across backends. This
+// saves callers from calling d.Get for every field in every callback.
+//
+// Set d.DecodeHooks to register additional mapstructure.DecodeHookFunc
+// conversions, e.g. string->time.Duration or string->net.IP, on top of
+// the normal coercion.
+func (d *FieldData) Decode(out interface{}) error {
+	raw := make(map[string]interface{}, len(d.Schema))
+	for field, schema := range d.Schema {
+		value, ok, err := d.GetOkErr(field)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %s", field, err)
+		}
+		if !ok && schema.DefaultFunc == nil {
+			value = schema.DefaultOrZero()
+		}
+		raw[field] = value
+	}
+
+	config := &mapstructure.DecoderConfig{
+		Result:           out,
+		WeaklyTypedInput: true,
+		DecodeHook:       mapstructure.ComposeDecodeHookFunc(d.DecodeHooks...),
+	}
+
+	decoder, err := mapstructure.NewDecoder(config)
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(raw)
+}
+
 // GetOk gets the value for the given field. The second return value
 // will be false if the key is invalid or the key is not set at all.
 func (d *FieldData) GetOk(k string) (interface{}, bool) {
@@ -101,7 +424,9 @@ func (d *FieldData) GetOk(k string) (interface{}, bool) {
 // GetOkErr is the most conservative of all the Get methods. It returns
 // whether key is set or not, but also an error value. The error value is
 // non-nil if the field doesn't exist or there was an error parsing the
-// field value.
+// field value. If the field is unset and its schema has a DefaultFunc,
+// that is invoked here; any error it returns is returned directly
+// instead of panicking.
 func (d *FieldData) GetOkErr(k string) (interface{}, bool, error) {
 	schema, ok := d.Schema[k]
 	if !ok {
@@ -110,8 +435,20 @@ func (d *FieldData) GetOkErr(k string) (interface{}, bool, error) {
 
 	switch schema.Type {
 	case TypeBool, TypeInt, TypeMap, TypeDurationSecond, TypeString,
-		TypeNameString, TypeSlice, TypeStringSlice, TypeCommaStringSlice:
-		return d.getPrimitive(k, schema)
+		TypeNameString, TypeSlice, TypeStringSlice, TypeCommaStringSlice,
+		TypeFloat, TypeStringMap, TypeSet, TypeJSONString, TypeBase64:
+		result, ok, err := d.getPrimitive(k, schema)
+		if err != nil {
+			return nil, ok, err
+		}
+		if !ok && schema.DefaultFunc != nil {
+			def, err := schema.DefaultFunc()
+			if err != nil {
+				return nil, false, fmt.Errorf("error getting default for %q: %s", k, err)
+			}
+			return def, false, nil
+		}
+		return result, ok, nil
 	default:
 		return nil, false,
 			fmt.Errorf("unknown field type %s for field %s", schema.Type, k)
@@ -236,6 +573,93 @@ func (d *FieldData) getPrimitive(
 		}
 		return strutil.TrimStrings(result), true, nil
 
+	case TypeFloat:
+		var result float64
+		if err := mapstructure.WeakDecode(raw, &result); err != nil {
+			return nil, true, err
+		}
+		return result, true, nil
+
+	case TypeStringMap:
+		result := map[string]string{}
+		switch inp := raw.(type) {
+		case map[string]string:
+			for k, v := range inp {
+				result[k] = v
+			}
+		case map[string]interface{}:
+			for k, v := range inp {
+				var s string
+				if err := mapstructure.WeakDecode(v, &s); err != nil {
+					return nil, true, err
+				}
+				result[k] = s
+			}
+		case string:
+			for _, pair := range strings.Split(inp, ",") {
+				if pair == "" {
+					continue
+				}
+				kv := strings.SplitN(pair, "=", 2)
+				if len(kv) != 2 {
+					return nil, true, fmt.Errorf("invalid key=value pair %q", pair)
+				}
+				result[kv[0]] = kv[1]
+			}
+		default:
+			return nil, true, fmt.Errorf("invalid input '%v' for string map field", raw)
+		}
+		return result, true, nil
+
+	case TypeSet:
+		var raws []interface{}
+		if err := mapstructure.WeakDecode(raw, &raws); err != nil {
+			return nil, true, err
+		}
+
+		seen := map[string]bool{}
+		var result []string
+		for _, r := range raws {
+			var s string
+			if err := mapstructure.WeakDecode(r, &s); err != nil {
+				return nil, true, err
+			}
+
+			if !seen[s] {
+				seen[s] = true
+				result = append(result, s)
+			}
+		}
+		sort.Strings(result)
+		return result, true, nil
+
+	case TypeJSONString:
+		switch inp := raw.(type) {
+		case string:
+			if !json.Valid([]byte(inp)) {
+				return nil, true, errors.New("invalid JSON in field value")
+			}
+			return inp, true, nil
+		case map[string]interface{}:
+			b, err := json.Marshal(inp)
+			if err != nil {
+				return nil, true, err
+			}
+			return string(b), true, nil
+		default:
+			return nil, true, fmt.Errorf("invalid input '%v' for JSON field", raw)
+		}
+
+	case TypeBase64:
+		var result string
+		if err := mapstructure.WeakDecode(raw, &result); err != nil {
+			return nil, true, err
+		}
+		if _, err := base64.StdEncoding.DecodeString(result); err != nil {
+			return nil, true, fmt.Errorf("invalid base64 value: %s", err)
+		}
+		return result, true, nil
+
 	default:
 		panic(fmt.Sprintf("Unknown type: %s", schema.Type))
 	}
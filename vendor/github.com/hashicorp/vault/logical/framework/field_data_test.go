@@ -0,0 +1,536 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/logical/framework/validation"
+	"github.com/mitchellh/mapstructure"
+)
+
+func TestFieldData_Validate_validateFunc(t *testing.T) {
+	t.Run("passes through with no warnings or errors", func(t *testing.T) {
+		d := &FieldData{
+			Raw: map[string]interface{}{"color": "red"},
+			Schema: map[string]*FieldSchema{
+				"color": {Type: TypeString, ValidateFunc: validation.StringInSlice([]string{"red", "blue"}, false)},
+			},
+		}
+
+		warnings, err := d.Validate()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(warnings) != 0 {
+			t.Fatalf("unexpected warnings: %v", warnings)
+		}
+	})
+
+	t.Run("aggregates ValidateFunc errors instead of failing fast", func(t *testing.T) {
+		d := &FieldData{
+			Raw: map[string]interface{}{"color": "green", "size": "huge"},
+			Schema: map[string]*FieldSchema{
+				"color": {Type: TypeString, ValidateFunc: validation.StringInSlice([]string{"red", "blue"}, false)},
+				"size":  {Type: TypeString, ValidateFunc: validation.StringInSlice([]string{"small", "large"}, false)},
+			},
+		}
+
+		_, err := d.Validate()
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if !strings.Contains(err.Error(), "color") || !strings.Contains(err.Error(), "size") {
+			t.Fatalf("expected aggregated errors for both fields, got: %s", err)
+		}
+	})
+
+	t.Run("surfaces warnings separately from errors", func(t *testing.T) {
+		d := &FieldData{
+			Raw: map[string]interface{}{"name": "x"},
+			Schema: map[string]*FieldSchema{
+				"name": {
+					Type: TypeString,
+					ValidateFunc: func(interface{}) ([]string, []error) {
+						return []string{"name is awfully short"}, nil
+					},
+				},
+			},
+		}
+
+		warnings, err := d.Validate()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(warnings) != 1 || warnings[0] != "name is awfully short" {
+			t.Fatalf("expected the ValidateFunc warning to surface, got: %v", warnings)
+		}
+	})
+}
+
+func TestFieldData_Validate_constraints(t *testing.T) {
+	cases := map[string]struct {
+		schema  map[string]*FieldSchema
+		raw     map[string]interface{}
+		wantErr bool
+	}{
+		"conflicts with, neither set": {
+			schema: map[string]*FieldSchema{
+				"a": {Type: TypeString, ConflictsWith: []string{"b"}},
+				"b": {Type: TypeString},
+			},
+			raw: map[string]interface{}{},
+		},
+		"conflicts with, both set": {
+			schema: map[string]*FieldSchema{
+				"a": {Type: TypeString, ConflictsWith: []string{"b"}},
+				"b": {Type: TypeString},
+			},
+			raw:     map[string]interface{}{"a": "x", "b": "y"},
+			wantErr: true,
+		},
+		"required with, satisfied": {
+			schema: map[string]*FieldSchema{
+				"a": {Type: TypeString, RequiredWith: []string{"b"}},
+				"b": {Type: TypeString},
+			},
+			raw: map[string]interface{}{"a": "x", "b": "y"},
+		},
+		"required with, missing": {
+			schema: map[string]*FieldSchema{
+				"a": {Type: TypeString, RequiredWith: []string{"b"}},
+				"b": {Type: TypeString},
+			},
+			raw:     map[string]interface{}{"a": "x"},
+			wantErr: true,
+		},
+		"exactly one of, none set": {
+			schema: map[string]*FieldSchema{
+				"a": {Type: TypeString, ExactlyOneOf: []string{"a", "b"}},
+				"b": {Type: TypeString, ExactlyOneOf: []string{"a", "b"}},
+			},
+			raw:     map[string]interface{}{},
+			wantErr: true,
+		},
+		"exactly one of, one set": {
+			schema: map[string]*FieldSchema{
+				"a": {Type: TypeString, ExactlyOneOf: []string{"a", "b"}},
+				"b": {Type: TypeString, ExactlyOneOf: []string{"a", "b"}},
+			},
+			raw: map[string]interface{}{"a": "x"},
+		},
+		"exactly one of, both set": {
+			schema: map[string]*FieldSchema{
+				"a": {Type: TypeString, ExactlyOneOf: []string{"a", "b"}},
+				"b": {Type: TypeString, ExactlyOneOf: []string{"a", "b"}},
+			},
+			raw:     map[string]interface{}{"a": "x", "b": "y"},
+			wantErr: true,
+		},
+		"at least one of, none set": {
+			schema: map[string]*FieldSchema{
+				"a": {Type: TypeString, AtLeastOneOf: []string{"a", "b"}},
+				"b": {Type: TypeString, AtLeastOneOf: []string{"a", "b"}},
+			},
+			raw:     map[string]interface{}{},
+			wantErr: true,
+		},
+		"at least one of, one set": {
+			schema: map[string]*FieldSchema{
+				"a": {Type: TypeString, AtLeastOneOf: []string{"a", "b"}},
+				"b": {Type: TypeString, AtLeastOneOf: []string{"a", "b"}},
+			},
+			raw: map[string]interface{}{"b": "y"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := &FieldData{Raw: tc.raw, Schema: tc.schema}
+			_, err := d.Validate()
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+// TestFieldData_Validate_exactlyOneOf_groupOrderIndependent guards
+// against a group being checked once per member when each member lists
+// the group in a different order, which would otherwise produce one
+// "exactly one of" error per member instead of a single error.
+func TestFieldData_Validate_exactlyOneOf_groupOrderIndependent(t *testing.T) {
+	d := &FieldData{
+		Raw: map[string]interface{}{"a": "x"},
+		Schema: map[string]*FieldSchema{
+			"a": {Type: TypeString, ExactlyOneOf: []string{"a", "b"}},
+			"b": {Type: TypeString, ExactlyOneOf: []string{"b", "a"}},
+		},
+	}
+
+	_, err := d.Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	d.Raw = map[string]interface{}{}
+	_, err = d.Validate()
+	if err == nil {
+		t.Fatalf("expected error when no member of the group is set")
+	}
+	if n := strings.Count(err.Error(), "exactly one of"); n != 1 {
+		t.Fatalf("expected a single \"exactly one of\" error, got %d: %s", n, err)
+	}
+}
+
+func TestFieldData_Validate_strict(t *testing.T) {
+	schema := map[string]*FieldSchema{
+		"known": {Type: TypeString},
+	}
+
+	t.Run("lenient by default", func(t *testing.T) {
+		d := &FieldData{
+			Raw:    map[string]interface{}{"known": "x", "typo": "y"},
+			Schema: schema,
+		}
+		if _, err := d.Validate(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("strict rejects unknown keys", func(t *testing.T) {
+		d := &FieldData{
+			Raw:    map[string]interface{}{"known": "x", "typo": "y"},
+			Schema: schema,
+			Strict: true,
+		}
+		_, err := d.Validate()
+		if err == nil {
+			t.Fatalf("expected error for unknown key")
+		}
+		if !strings.Contains(err.Error(), `"typo"`) {
+			t.Fatalf("expected error to name the unknown key, got: %s", err)
+		}
+	})
+
+	t.Run("strict allows only known keys", func(t *testing.T) {
+		d := &FieldData{
+			Raw:    map[string]interface{}{"known": "x"},
+			Schema: schema,
+			Strict: true,
+		}
+		if _, err := d.Validate(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+}
+
+func TestFieldData_Decode(t *testing.T) {
+	d := &FieldData{
+		Raw: map[string]interface{}{
+			"name":   "vault",
+			"ttl":    "30",
+			"active": true,
+		},
+		Schema: map[string]*FieldSchema{
+			"name":   {Type: TypeString},
+			"ttl":    {Type: TypeInt},
+			"active": {Type: TypeBool},
+		},
+	}
+
+	var out struct {
+		Name   string `mapstructure:"name"`
+		TTL    int    `mapstructure:"ttl"`
+		Active bool   `mapstructure:"active"`
+	}
+
+	if err := d.Decode(&out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if out.Name != "vault" || out.TTL != 30 || !out.Active {
+		t.Fatalf("unexpected decode result: %+v", out)
+	}
+}
+
+func TestFieldData_Decode_appliesSchemaDefaults(t *testing.T) {
+	d := &FieldData{
+		Raw: map[string]interface{}{},
+		Schema: map[string]*FieldSchema{
+			"name": {Type: TypeString, Default: "fallback"},
+		},
+	}
+
+	var out struct {
+		Name string `mapstructure:"name"`
+	}
+
+	if err := d.Decode(&out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.Name != "fallback" {
+		t.Fatalf("expected default value %q, got %q", "fallback", out.Name)
+	}
+}
+
+func TestFieldData_Decode_coercionError(t *testing.T) {
+	d := &FieldData{
+		Raw: map[string]interface{}{"count": "not-an-int"},
+		Schema: map[string]*FieldSchema{
+			"count": {Type: TypeInt},
+		},
+	}
+
+	var out struct {
+		Count int `mapstructure:"count"`
+	}
+
+	if err := d.Decode(&out); err == nil {
+		t.Fatalf("expected error for unparsable field")
+	}
+}
+
+func TestFieldData_Decode_decodeHooks(t *testing.T) {
+	d := &FieldData{
+		Raw: map[string]interface{}{"timeout": "30s"},
+		Schema: map[string]*FieldSchema{
+			"timeout": {Type: TypeString},
+		},
+		DecodeHooks: []mapstructure.DecodeHookFunc{
+			mapstructure.StringToTimeDurationHookFunc(),
+		},
+	}
+
+	var out struct {
+		Timeout time.Duration `mapstructure:"timeout"`
+	}
+
+	if err := d.Decode(&out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.Timeout != 30*time.Second {
+		t.Fatalf("expected 30s, got %s", out.Timeout)
+	}
+}
+
+func TestFieldData_Decode_embeddedStruct(t *testing.T) {
+	type base struct {
+		Name string `mapstructure:"name"`
+	}
+
+	d := &FieldData{
+		Raw: map[string]interface{}{
+			"name": "vault",
+			"ttl":  5,
+		},
+		Schema: map[string]*FieldSchema{
+			"name": {Type: TypeString},
+			"ttl":  {Type: TypeInt},
+		},
+	}
+
+	var out struct {
+		base `mapstructure:",squash"`
+		TTL  int `mapstructure:"ttl"`
+	}
+
+	if err := d.Decode(&out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.Name != "vault" || out.TTL != 5 {
+		t.Fatalf("unexpected decode result: %+v", out)
+	}
+}
+
+func TestFieldData_getPrimitive_newTypes(t *testing.T) {
+	cases := map[string]struct {
+		schema  *FieldSchema
+		raw     interface{}
+		expect  interface{}
+		wantErr bool
+	}{
+		"float from int": {
+			schema: &FieldSchema{Type: TypeFloat},
+			raw:    4,
+			expect: float64(4),
+		},
+		"float from string": {
+			schema: &FieldSchema{Type: TypeFloat},
+			raw:    "4.5",
+			expect: float64(4.5),
+		},
+		"string map from map[string]interface{}": {
+			schema: &FieldSchema{Type: TypeStringMap},
+			raw:    map[string]interface{}{"a": "1", "b": 2},
+			expect: map[string]string{"a": "1", "b": "2"},
+		},
+		"string map from k=v,k=v string": {
+			schema: &FieldSchema{Type: TypeStringMap},
+			raw:    "a=1,b=2",
+			expect: map[string]string{"a": "1", "b": "2"},
+		},
+		"string map from malformed string": {
+			schema:  &FieldSchema{Type: TypeStringMap},
+			raw:     "a=1,b",
+			wantErr: true,
+		},
+		"set dedupes and sorts": {
+			schema: &FieldSchema{Type: TypeSet},
+			raw:    []interface{}{"b", "a", "b"},
+			expect: []string{"a", "b"},
+		},
+		"set keeps distinct values regardless of hash collisions": {
+			schema: &FieldSchema{Type: TypeSet},
+			raw:    []interface{}{"costarring", "liquid"},
+			expect: []string{"costarring", "liquid"},
+		},
+		"JSON string passthrough": {
+			schema: &FieldSchema{Type: TypeJSONString},
+			raw:    `{"a":1}`,
+			expect: `{"a":1}`,
+		},
+		"JSON string invalid": {
+			schema:  &FieldSchema{Type: TypeJSONString},
+			raw:     `{not json}`,
+			wantErr: true,
+		},
+		"JSON string from map": {
+			schema: &FieldSchema{Type: TypeJSONString},
+			raw:    map[string]interface{}{"a": float64(1)},
+			expect: `{"a":1}`,
+		},
+		"base64 valid": {
+			schema: &FieldSchema{Type: TypeBase64},
+			raw:    "aGVsbG8=",
+			expect: "aGVsbG8=",
+		},
+		"base64 invalid": {
+			schema:  &FieldSchema{Type: TypeBase64},
+			raw:     "not base64!!",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := &FieldData{
+				Raw:    map[string]interface{}{"value": tc.raw},
+				Schema: map[string]*FieldSchema{"value": tc.schema},
+			}
+
+			result, ok, err := d.getPrimitive("value", tc.schema)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !ok {
+				t.Fatalf("expected ok=true")
+			}
+			if !reflect.DeepEqual(result, tc.expect) {
+				t.Fatalf("expected %#v, got %#v", tc.expect, result)
+			}
+		})
+	}
+}
+
+func TestFieldData_GetOkErr_defaultFunc(t *testing.T) {
+	d := &FieldData{
+		Raw: map[string]interface{}{},
+		Schema: map[string]*FieldSchema{
+			"value": {
+				Type:        TypeString,
+				DefaultFunc: func() (interface{}, error) { return "from-func", nil },
+			},
+		},
+	}
+
+	result, ok, err := d.GetOkErr("value")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if ok {
+		t.Fatalf("expected ok=false for an unset field")
+	}
+	if result != "from-func" {
+		t.Fatalf("expected DefaultFunc value, got %#v", result)
+	}
+}
+
+func TestFieldData_Get_defaultFuncCalledOnce(t *testing.T) {
+	calls := 0
+	d := &FieldData{
+		Raw: map[string]interface{}{},
+		Schema: map[string]*FieldSchema{
+			"value": {
+				Type: TypeString,
+				DefaultFunc: func() (interface{}, error) {
+					calls++
+					return fmt.Sprintf("call-%d", calls), nil
+				},
+			},
+		},
+	}
+
+	result := d.Get("value")
+	if calls != 1 {
+		t.Fatalf("expected DefaultFunc to be called once, got %d calls", calls)
+	}
+	if result != "call-1" {
+		t.Fatalf("expected %q, got %#v", "call-1", result)
+	}
+}
+
+func TestFieldData_Decode_defaultFuncCalledOnce(t *testing.T) {
+	calls := 0
+	d := &FieldData{
+		Raw: map[string]interface{}{},
+		Schema: map[string]*FieldSchema{
+			"value": {
+				Type: TypeString,
+				DefaultFunc: func() (interface{}, error) {
+					calls++
+					return fmt.Sprintf("call-%d", calls), nil
+				},
+			},
+		},
+	}
+
+	var out struct {
+		Value string `mapstructure:"value"`
+	}
+	if err := d.Decode(&out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected DefaultFunc to be called once, got %d calls", calls)
+	}
+}
+
+func TestFieldData_GetOkErr_defaultFuncError(t *testing.T) {
+	d := &FieldData{
+		Raw: map[string]interface{}{},
+		Schema: map[string]*FieldSchema{
+			"value": {
+				Type:        TypeString,
+				DefaultFunc: func() (interface{}, error) { return nil, errors.New("boom") },
+			},
+		},
+	}
+
+	_, _, err := d.GetOkErr("value")
+	if err == nil {
+		t.Fatalf("expected error from DefaultFunc to propagate")
+	}
+}
@@ -0,0 +1,120 @@
+// Package validation provides reusable FieldSchema.ValidateFunc
+// implementations, modeled on Terraform's helper/validation package, so
+// that backends can compose common checks instead of rewriting the
+// coercion loop themselves.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// StringInSlice returns a ValidateFunc that checks if the field value is
+// a member of valid. When ignoreCase is true the comparison is
+// case-insensitive.
+func StringInSlice(valid []string, ignoreCase bool) func(interface{}) ([]string, []error) {
+	return func(i interface{}) ([]string, []error) {
+		v, ok := i.(string)
+		if !ok {
+			return nil, []error{fmt.Errorf("expected type of %v to be string", i)}
+		}
+
+		for _, str := range valid {
+			if v == str || (ignoreCase && strings.EqualFold(v, str)) {
+				return nil, nil
+			}
+		}
+
+		return nil, []error{fmt.Errorf("expected %v to be one of %v", i, valid)}
+	}
+}
+
+// IntBetween returns a ValidateFunc that checks if the field value is an
+// int between min and max, inclusive.
+func IntBetween(min, max int) func(interface{}) ([]string, []error) {
+	return func(i interface{}) ([]string, []error) {
+		v, ok := i.(int)
+		if !ok {
+			return nil, []error{fmt.Errorf("expected type of %v to be int", i)}
+		}
+
+		if v < min || v > max {
+			return nil, []error{fmt.Errorf("expected %v to be in the range (%d - %d), got %d", i, min, max, v)}
+		}
+
+		return nil, nil
+	}
+}
+
+// StringLenBetween returns a ValidateFunc that checks if the field
+// value's length is between min and max, inclusive.
+func StringLenBetween(min, max int) func(interface{}) ([]string, []error) {
+	return func(i interface{}) ([]string, []error) {
+		v, ok := i.(string)
+		if !ok {
+			return nil, []error{fmt.Errorf("expected type of %v to be string", i)}
+		}
+
+		if len(v) < min || len(v) > max {
+			return nil, []error{fmt.Errorf("expected length of %v to be in the range (%d - %d), got %d", i, min, max, len(v))}
+		}
+
+		return nil, nil
+	}
+}
+
+// StringMatch returns a ValidateFunc that checks if the field value
+// matches r. message, if non-empty, is used as the error text instead of
+// the default "expected ... to match" message.
+func StringMatch(r *regexp.Regexp, message string) func(interface{}) ([]string, []error) {
+	return func(i interface{}) ([]string, []error) {
+		v, ok := i.(string)
+		if !ok {
+			return nil, []error{fmt.Errorf("expected type of %v to be string", i)}
+		}
+
+		if !r.MatchString(v) {
+			if message != "" {
+				return nil, []error{errors.New(message)}
+			}
+			return nil, []error{fmt.Errorf("expected %v to match regular expression %q", i, r)}
+		}
+
+		return nil, nil
+	}
+}
+
+// IsCIDR is a ValidateFunc that checks if the field value is a valid
+// CIDR network.
+func IsCIDR(i interface{}) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %v to be string", i)}
+	}
+
+	if _, _, err := net.ParseCIDR(v); err != nil {
+		return nil, []error{fmt.Errorf("expected %s to contain a valid CIDR, got: %s", v, err)}
+	}
+
+	return nil, nil
+}
+
+// IsURL is a ValidateFunc that checks if the field value parses as a URL
+// with both a scheme and a host.
+func IsURL(i interface{}) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %v to be string", i)}
+	}
+
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, []error{fmt.Errorf("expected %s to be a valid URL", v)}
+	}
+
+	return nil, nil
+}
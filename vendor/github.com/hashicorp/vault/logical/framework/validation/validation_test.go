@@ -0,0 +1,162 @@
+package validation
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestStringInSlice(t *testing.T) {
+	cases := map[string]struct {
+		valid      []string
+		ignoreCase bool
+		value      interface{}
+		wantErr    bool
+	}{
+		"match":            {valid: []string{"a", "b"}, value: "a"},
+		"no match":         {valid: []string{"a", "b"}, value: "c", wantErr: true},
+		"case mismatch":    {valid: []string{"a"}, value: "A", wantErr: true},
+		"case insensitive": {valid: []string{"a"}, ignoreCase: true, value: "A"},
+		"non-string input": {valid: []string{"a"}, value: 1, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := StringInSlice(tc.valid, tc.ignoreCase)(tc.value)
+			if tc.wantErr && len(errs) == 0 {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.wantErr && len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestIntBetween(t *testing.T) {
+	cases := map[string]struct {
+		min, max int
+		value    interface{}
+		wantErr  bool
+	}{
+		"in range":       {min: 1, max: 10, value: 5},
+		"at lower bound": {min: 1, max: 10, value: 1},
+		"at upper bound": {min: 1, max: 10, value: 10},
+		"below range":    {min: 1, max: 10, value: 0, wantErr: true},
+		"above range":    {min: 1, max: 10, value: 11, wantErr: true},
+		"non-int input":  {min: 1, max: 10, value: "5", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := IntBetween(tc.min, tc.max)(tc.value)
+			if tc.wantErr && len(errs) == 0 {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.wantErr && len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestStringLenBetween(t *testing.T) {
+	cases := map[string]struct {
+		min, max int
+		value    interface{}
+		wantErr  bool
+	}{
+		"in range":   {min: 2, max: 5, value: "abc"},
+		"too short":  {min: 2, max: 5, value: "a", wantErr: true},
+		"too long":   {min: 2, max: 5, value: "abcdef", wantErr: true},
+		"non-string": {min: 2, max: 5, value: 5, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := StringLenBetween(tc.min, tc.max)(tc.value)
+			if tc.wantErr && len(errs) == 0 {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.wantErr && len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestStringMatch(t *testing.T) {
+	re := regexp.MustCompile(`^[a-z]+$`)
+
+	cases := map[string]struct {
+		message string
+		value   interface{}
+		wantErr bool
+	}{
+		"matches":                {value: "abc"},
+		"does not match":         {value: "ABC", wantErr: true},
+		"does not match message": {value: "ABC", message: "must be lowercase", wantErr: true},
+		"non-string":             {value: 1, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := StringMatch(re, tc.message)(tc.value)
+			if tc.wantErr && len(errs) == 0 {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.wantErr && len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if tc.wantErr && tc.message != "" && errs[0].Error() != tc.message {
+				t.Fatalf("expected message %q, got %q", tc.message, errs[0].Error())
+			}
+		})
+	}
+}
+
+func TestIsCIDR(t *testing.T) {
+	cases := map[string]struct {
+		value   interface{}
+		wantErr bool
+	}{
+		"valid CIDR":   {value: "192.168.1.0/24"},
+		"invalid CIDR": {value: "not-a-cidr", wantErr: true},
+		"non-string":   {value: 1, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := IsCIDR(tc.value)
+			if tc.wantErr && len(errs) == 0 {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.wantErr && len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestIsURL(t *testing.T) {
+	cases := map[string]struct {
+		value   interface{}
+		wantErr bool
+	}{
+		"valid URL":      {value: "https://example.com/path"},
+		"missing scheme": {value: "example.com/path", wantErr: true},
+		"missing host":   {value: "file:///path", wantErr: true},
+		"non-string":     {value: 1, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := IsURL(tc.value)
+			if tc.wantErr && len(errs) == 0 {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.wantErr && len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+		})
+	}
+}